@@ -0,0 +1,170 @@
+//go:build acceptance
+
+package stdlib_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// repository is a large public repository used to benchmark the analyzer.
+type repository struct {
+	name string
+	url  string
+	ref  string
+}
+
+// repositories returns the repositories to benchmark.
+func repositories() []repository {
+	return []repository{
+		{name: "prometheus", url: "https://github.com/prometheus/prometheus", ref: "v2.53.0"},
+		{name: "terraform", url: "https://github.com/hashicorp/terraform", ref: "v1.9.0"},
+	}
+}
+
+// BenchmarkRepositories benchmarks the analyzer on large public repositories, which are cloned into
+// $STDLIB_BENCH_CACHE or the user cache directory on the first run. Run it using:
+//
+//	go test -tags acceptance -run '^$' -bench Repositories -benchmem
+func BenchmarkRepositories(b *testing.B) {
+	for _, repo := range repositories() {
+		pkgs := loadRepository(b, repo)
+		b.Run(repo.name, func(b *testing.B) { benchmarkAnalyzer(b, pkgs) })
+	}
+}
+
+// baseline is the result of analyzing a repository, recorded to detect regressions in later runs.
+type baseline struct {
+	Diagnostics int   `json:"diagnostics"`
+	NsPerOp     int64 `json:"nsPerOp"`
+	AllocsPerOp int64 `json:"allocsPerOp"`
+	BytesPerOp  int64 `json:"bytesPerOp"`
+}
+
+// TestRepositories compares the results of analyzing large public repositories with the baseline
+// recorded by a previous run next to the cloned repository. It fails if the number of diagnostics
+// changes or allocations grow by more than 10%. Wall-time depends on the machine so changes are only
+// logged. The baseline is recorded on the first run, or when $STDLIB_BENCH_UPDATE is set. Run it using:
+//
+//	go test -tags acceptance -run Repositories -v
+func TestRepositories(t *testing.T) {
+	for _, repo := range repositories() {
+		t.Run(repo.name, func(t *testing.T) {
+			pkgs := loadRepository(t, repo)
+
+			res := testing.Benchmark(func(b *testing.B) { benchmarkAnalyzer(b, pkgs) })
+			got := baseline{
+				Diagnostics: int(res.Extra["diagnostics"]),
+				NsPerOp:     res.NsPerOp(),
+				AllocsPerOp: res.AllocsPerOp(),
+				BytesPerOp:  res.AllocedBytesPerOp(),
+			}
+			t.Logf("%d diagnostics, %d ns/op, %d allocs/op, %d B/op",
+				got.Diagnostics, got.NsPerOp, got.AllocsPerOp, got.BytesPerOp)
+
+			file := repositoryDir(t, repo) + ".json"
+			want, ok, err := readBaseline(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok || os.Getenv("STDLIB_BENCH_UPDATE") != "" {
+				if err := writeBaseline(file, got); err != nil {
+					t.Fatal(err)
+				}
+				t.Logf("recorded baseline in %s", file)
+				return
+			}
+
+			if got.Diagnostics != want.Diagnostics {
+				t.Errorf("got %d diagnostics, want %d", got.Diagnostics, want.Diagnostics)
+			}
+			if float64(got.AllocsPerOp) > float64(want.AllocsPerOp)*1.1 {
+				t.Errorf("got %d allocs/op, want at most 10%% more than %d", got.AllocsPerOp, want.AllocsPerOp)
+			}
+			t.Logf("wall-time changed by %+.1f%%", (float64(got.NsPerOp)/float64(want.NsPerOp)-1)*100)
+		})
+	}
+}
+
+// loadRepository clones the repository if needed and loads its packages.
+func loadRepository(tb testing.TB, repo repository) []*packages.Package {
+	tb.Helper()
+
+	dir := repositoryDir(tb, repo)
+	if err := cloneRepository(dir, repo.url, repo.ref); err != nil {
+		tb.Fatal(err)
+	}
+
+	return loadPackages(tb, dir, "./...")
+}
+
+// repositoryDir returns the directory the repository is cloned into.
+func repositoryDir(tb testing.TB, repo repository) string {
+	tb.Helper()
+
+	cache := os.Getenv("STDLIB_BENCH_CACHE")
+	if cache == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		cache = filepath.Join(dir, "stdlib-bench")
+	}
+
+	return filepath.Join(cache, repo.name+"@"+repo.ref)
+}
+
+// cloneRepository clones the ref of the repository into dir, unless it already exists.
+func cloneRepository(dir, url, ref string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "clone", "--quiet", "--depth=1", "--branch="+ref, url, tmp)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to clone %s: %w: %s", url, err, strings.TrimSpace(string(output)))
+	}
+
+	return os.Rename(tmp, dir)
+}
+
+// readBaseline reads a baseline from a file. It returns false if the file does not exist.
+func readBaseline(name string) (baseline, bool, error) {
+	var b baseline
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, false, nil
+	}
+	if err != nil {
+		return b, false, err
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, false, fmt.Errorf("failed to parse baseline from %s: %w", name, err)
+	}
+	return b, true, nil
+}
+
+// writeBaseline writes a baseline to a file.
+func writeBaseline(name string, b baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o600)
+}
@@ -1,11 +1,159 @@
 // Package main contains the stdlib command.
+//
+// In addition to the standard analysis flags, the command accepts a -tags flag
+// containing a comma-separated list of build tags to use when loading packages.
+// The flag may be repeated to analyze the packages once for each set of tags,
+// which cannot be combined with -json. Tags set in GOFLAGS are added to each set.
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
 	"github.com/abemedia/stdlib"
 	"golang.org/x/tools/go/analysis/singlechecker"
 )
 
 func main() {
-	singlechecker.Main(stdlib.NewAnalyzer())
+	args, tagSets := parseTags(os.Args[1:])
+
+	if len(tagSets) > 1 {
+		if hasJSON(args) {
+			fmt.Fprintln(os.Stderr, "stdlib: -json cannot be used with multiple -tags flags")
+			os.Exit(2)
+		}
+		os.Exit(runEach(args, tagSets))
+	}
+
+	if len(tagSets) == 1 {
+		if err := os.Setenv("GOFLAGS", goflags(os.Getenv("GOFLAGS"), tagSets[0])); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	os.Args = append(os.Args[:1], args...)
+	singlechecker.Main(stdlib.NewAnalyzer(packs...))
+}
+
+// boolFlags contains the flags of the analysis driver which do not take a separate value.
+//
+//nolint:gochecknoglobals
+var boolFlags = map[string]bool{
+	"all": true, "diff": true, "fix": true, "flags": true, "h": true, "help": true,
+	"json": true, "source": true, "test": true, "v": true, "V": true,
+}
+
+// parseFlag parses the flag at args[i], including its value if it is passed as a separate argument.
+// It returns the index of the next argument, or false if args[i] is not a flag. Like flag.Parse,
+// "--" and the first positional argument terminate the flags.
+func parseFlag(args []string, i int) (name, value string, hasValue bool, next int, ok bool) {
+	arg := args[i]
+	if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+		return "", "", false, i, false
+	}
+
+	name, value, hasValue = strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+	if !hasValue && !boolFlags[name] && i+1 < len(args) {
+		return name, args[i+1], true, i + 2, true
+	}
+	return name, value, hasValue, i + 1, true
+}
+
+// parseTags removes all -tags flags from args and returns the remaining
+// arguments and the value of each -tags flag.
+func parseTags(args []string) ([]string, []string) {
+	var rest, tagSets []string
+
+	for i := 0; i < len(args); {
+		name, value, hasValue, next, ok := parseFlag(args, i)
+		if !ok {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if name == "tags" && hasValue {
+			tagSets = append(tagSets, value)
+		} else {
+			rest = append(rest, args[i:next]...) // A missing value is reported by the flag package.
+		}
+		i = next
+	}
+
+	return rest, tagSets
+}
+
+// hasJSON reports whether args enable the -json flag.
+func hasJSON(args []string) bool {
+	var enabled bool
+	for i := 0; i < len(args); {
+		name, value, hasValue, next, ok := parseFlag(args, i)
+		if !ok {
+			break
+		}
+		if name == "json" {
+			enabled = true
+			if hasValue {
+				enabled, _ = strconv.ParseBool(value)
+			}
+		}
+		i = next
+	}
+	return enabled
+}
+
+// goflags returns the GOFLAGS environment variable with the build tags added.
+// Tags already set in GOFLAGS are kept, as the go command only uses the last -tags flag.
+// Space-separated tags are accepted for compatibility with older go versions.
+func goflags(env, tags string) string {
+	var flags, allTags []string
+	for _, flag := range strings.Fields(env) {
+		name, value, _ := strings.Cut(strings.TrimPrefix(flag, "-"), "=")
+		if name == "tags" || name == "-tags" {
+			allTags = append(allTags, splitTags(value)...)
+			continue
+		}
+		flags = append(flags, flag)
+	}
+
+	allTags = append(allTags, splitTags(tags)...)
+	return strings.Join(append(flags, "-tags="+strings.Join(allTags, ",")), " ")
+}
+
+// splitTags splits a comma or space-separated list of build tags.
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// runEach runs the command once for each set of tags and returns the highest exit code.
+// Findings in files which are included by multiple sets of tags are reported once per set.
+func runEach(args, tagSets []string) int {
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var exitCode int
+	for _, tags := range tagSets {
+		cmd := exec.Command(executable, args...)
+		cmd.Env = append(os.Environ(), "GOFLAGS="+goflags(os.Getenv("GOFLAGS"), tags))
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			exitCode = max(exitCode, exitErr.ExitCode())
+		}
+	}
+
+	return exitCode
 }
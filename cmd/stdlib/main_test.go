@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantArgs []string
+		wantTags []string
+	}{
+		{
+			args:     []string{"./..."},
+			wantArgs: []string{"./..."},
+		},
+		{
+			args:     []string{"-tags", "linux", "./..."},
+			wantArgs: []string{"./..."},
+			wantTags: []string{"linux"},
+		},
+		{
+			args:     []string{"-fix", "--tags=linux,integration", "-tags=", "-c", "1", "./..."},
+			wantArgs: []string{"-fix", "-c", "1", "./..."},
+			wantTags: []string{"linux,integration", ""},
+		},
+		{
+			args:     []string{"-tags=linux", "--", "-tags=windows"},
+			wantArgs: []string{"--", "-tags=windows"},
+			wantTags: []string{"linux"},
+		},
+		{
+			args:     []string{"./...", "-tags"},
+			wantArgs: []string{"./...", "-tags"},
+		},
+		{
+			args:     []string{"-fix", "-tags"},
+			wantArgs: []string{"-fix", "-tags"},
+		},
+		{
+			args:     []string{"tags", "linux"},
+			wantArgs: []string{"tags", "linux"},
+		},
+		{
+			args:     []string{"-diff-base", "tags", "./..."},
+			wantArgs: []string{"-diff-base", "tags", "./..."},
+		},
+		{
+			args:     []string{"-diff-base", "-tags", "-tags", "linux", "./..."},
+			wantArgs: []string{"-diff-base", "-tags", "./..."},
+			wantTags: []string{"linux"},
+		},
+		{
+			args:     []string{"./...", "-tags=linux"},
+			wantArgs: []string{"./...", "-tags=linux"},
+		},
+	}
+
+	for _, test := range tests {
+		args, tags := parseTags(test.args)
+		if !reflect.DeepEqual(args, test.wantArgs) {
+			t.Errorf("parseTags(%q) args = %q, want %q", test.args, args, test.wantArgs)
+		}
+		if !reflect.DeepEqual(tags, test.wantTags) {
+			t.Errorf("parseTags(%q) tags = %q, want %q", test.args, tags, test.wantTags)
+		}
+	}
+}
+
+func TestGoflags(t *testing.T) {
+	tests := []struct {
+		env, tags, want string
+	}{
+		{"", "linux", "-tags=linux"},
+		{"", "linux,integration", "-tags=linux,integration"},
+		{"", "linux integration", "-tags=linux,integration"},
+		{"", "", "-tags="},
+		{"-mod=vendor", "linux", "-mod=vendor -tags=linux"},
+		{"-tags=foo -mod=vendor", "linux", "-mod=vendor -tags=foo,linux"},
+		{"-tags=foo,bar --tags=baz", "", "-tags=foo,bar,baz"},
+	}
+
+	for _, test := range tests {
+		if got := goflags(test.env, test.tags); got != test.want {
+			t.Errorf("goflags(%q, %q) = %q, want %q", test.env, test.tags, got, test.want)
+		}
+	}
+}
+
+func TestHasJSON(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"./..."}, false},
+		{[]string{"-json", "./..."}, true},
+		{[]string{"--json=true", "./..."}, true},
+		{[]string{"-json=false", "./..."}, false},
+		{[]string{"-fix", "--", "-json"}, false},
+		{[]string{"-json=0", "./..."}, false},
+		{[]string{"-json=f", "./..."}, false},
+		{[]string{"-json=1", "./..."}, true},
+		{[]string{"-json", "-json=false", "./..."}, false},
+		{[]string{"-rules", "json", "./..."}, false},
+		{[]string{"json"}, false},
+		{[]string{"./...", "-json"}, false},
+		{[]string{"-fix", "-json", "./..."}, true},
+	}
+
+	for _, test := range tests {
+		if got := hasJSON(test.args); got != test.want {
+			t.Errorf("hasJSON(%q) = %t, want %t", test.args, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+import "github.com/abemedia/stdlib"
+
+// packs contains the rule packs compiled into the command in addition to the
+// built-in rules. Rule packs from other modules can be added here, or loaded
+// without rebuilding the command by passing a JSON file to the -rules flag.
+//
+//nolint:gochecknoglobals
+var packs = []stdlib.RulePack{}
@@ -0,0 +1,217 @@
+package stdlib
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"maps"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lineRange is an inclusive range of line numbers.
+type lineRange struct {
+	start, end int
+}
+
+// changes maps absolute file names to the lines which were added or modified.
+// A nil changes value contains every line.
+type changes map[string][]lineRange
+
+// contains reports whether any line between pos and end was changed.
+func (c changes) contains(fset *token.FileSet, pos, end token.Pos) bool {
+	if c == nil {
+		return true
+	}
+
+	start := fset.Position(pos)
+	stop := start
+	if end.IsValid() {
+		stop = fset.Position(end)
+	}
+
+	filename := start.Filename
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+
+	for _, r := range c[filename] {
+		if start.Line <= r.end && stop.Line >= r.start {
+			return true
+		}
+	}
+	return false
+}
+
+// gitChanges returns the lines changed in the working tree relative to the git ref base.
+func gitChanges(base string) (changes, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository: %w", gitError(err))
+	}
+	root := strings.TrimSpace(string(out))
+
+	diff := exec.Command(
+		"git", "diff", "--no-color", "--no-ext-diff", "--no-relative", "--unified=0",
+		"--src-prefix=a/", "--dst-prefix=b/", base, "--", "*.go",
+	)
+	diff.Dir = root
+	out, err = diff.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %q: %w", base, gitError(err))
+	}
+
+	c, err := parseDiff(bytes.NewReader(out), root)
+	if err != nil {
+		return nil, err
+	}
+
+	// Untracked files are not included in the diff, so all of their lines are new.
+	untracked := exec.Command("git", "ls-files", "-z", "--others", "--exclude-standard", "--", "*.go")
+	untracked.Dir = root
+	out, err = untracked.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", gitError(err))
+	}
+	maps.Copy(c, parseUntracked(out, root))
+
+	return c, nil
+}
+
+// gitError adds the output written to stderr by git to the error.
+func gitError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// parseDiff parses a unified diff and returns the changed lines of each file.
+// File names in the diff are resolved relative to root.
+func parseDiff(r io.Reader, root string) (changes, error) {
+	c := make(changes)
+
+	var (
+		filename           string
+		oldLines, newLines int // remaining lines in the current hunk
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Lines within a hunk are never headers, even if their content looks like one.
+		if oldLines > 0 || newLines > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLines--
+			case strings.HasPrefix(line, "-"):
+				oldLines--
+			case strings.HasPrefix(line, `\`): // "\ No newline at end of file"
+			default:
+				oldLines--
+				newLines--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if strings.HasPrefix(name, `"`) {
+				unquoted, err := strconv.Unquote(name)
+				if err != nil {
+					return nil, fmt.Errorf("invalid file name in diff: %s", name)
+				}
+				name = unquoted
+			}
+			if name == "/dev/null" {
+				filename = "" // File was deleted.
+				continue
+			}
+			filename = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+				filename = resolved
+			}
+
+		case strings.HasPrefix(line, "@@ "):
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			oldLines, newLines = h.oldLines, h.newLines
+			if filename != "" && newLines > 0 {
+				c[filename] = append(c[filename], lineRange{start: h.start, end: h.start + newLines - 1})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// hunkHeader is a parsed hunk header.
+type hunkHeader struct {
+	start              int // first new line
+	oldLines, newLines int // number of old and new lines
+}
+
+// parseHunkHeader parses a hunk header such as "@@ -1,2 +3,4 @@".
+func parseHunkHeader(line string) (hunkHeader, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return hunkHeader{}, fmt.Errorf("invalid hunk header in diff: %s", line)
+	}
+
+	_, oldLines, err := parseHunkRange(fields[1][1:])
+	if err != nil {
+		return hunkHeader{}, fmt.Errorf("invalid hunk header in diff: %s", line)
+	}
+	start, newLines, err := parseHunkRange(fields[2][1:])
+	if err != nil {
+		return hunkHeader{}, fmt.Errorf("invalid hunk header in diff: %s", line)
+	}
+
+	return hunkHeader{start: start, oldLines: oldLines, newLines: newLines}, nil
+}
+
+// parseHunkRange parses a range such as "3,4" or "3", returning the start and number of lines.
+func parseHunkRange(s string) (int, int, error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// parseUntracked parses the NUL-separated output of git ls-files and returns each file as fully changed.
+// File names are resolved relative to root.
+func parseUntracked(out []byte, root string) changes {
+	c := make(changes)
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+			filename = resolved
+		}
+		c[filename] = []lineRange{{start: 1, end: math.MaxInt}}
+	}
+	return c
+}
@@ -0,0 +1,126 @@
+package stdlib
+
+import (
+	"go/token"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	root := t.TempDir()
+
+	diff := `diff --git a/foo.go b/foo.go
+index 1111111..2222222 100644
+--- a/foo.go
++++ b/foo.go
+@@ -3 +3 @@ import "github.com/samber/lo"
+-	a := lo.Min(s)
++	a := lo.Max(s)
+@@ -10,2 +10,0 @@ func foo() {
+-	b := 1
+-	c := 2
+@@ -20,0 +19,3 @@ func bar() {
++	d := lo.Keys(m)
++	e := lo.Values(m)
++	f := lo.Drop(s, 1)
+diff --git a/bar/bar.go b/bar/bar.go
+new file mode 100644
+--- /dev/null
++++ b/bar/bar.go
+@@ -0,0 +1,2 @@
++package bar
++
+diff --git a/baz.go b/baz.go
+deleted file mode 100644
+--- a/baz.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package baz
+diff --git "a/with\ttab.go" "b/with\ttab.go"
+--- "a/with\ttab.go"
++++ "b/with\ttab.go"
+@@ -1 +1 @@
+-package a
++package b
+diff --git a/qux.go b/qux.go
+--- a/qux.go
++++ b/qux.go
+@@ -5,2 +5,3 @@ func qux() {
+--- x
+ 	y := 1
++++ fake.go
++	z := 2
+@@ -9 +10 @@ func qux() {
+-	w := 3
+\ No newline at end of file
++	w := 4
+\ No newline at end of file
+`
+
+	got, err := parseDiff(strings.NewReader(diff), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := changes{
+		filepath.Join(root, "foo.go"):        {{3, 3}, {19, 21}},
+		filepath.Join(root, "bar", "bar.go"): {{1, 2}},
+		filepath.Join(root, "with\ttab.go"):  {{1, 1}},
+		filepath.Join(root, "qux.go"):        {{5, 7}, {10, 10}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseUntracked(t *testing.T) {
+	root := t.TempDir()
+
+	got := parseUntracked([]byte("foo.go\x00bar/bar.go\x00"), root)
+	want := changes{
+		filepath.Join(root, "foo.go"):        {{1, math.MaxInt}},
+		filepath.Join(root, "bar", "bar.go"): {{1, math.MaxInt}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestChangesContains(t *testing.T) {
+	root := t.TempDir()
+	filename := filepath.Join(root, "foo.go")
+
+	fset := token.NewFileSet()
+	file := fset.AddFile(filename, -1, 100)
+	file.SetLines([]int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}) // 10 lines of 10 bytes each.
+	line := func(n int) token.Pos { return file.LineStart(n) }
+
+	c := changes{filename: {{3, 3}, {6, 7}}}
+
+	tests := []struct {
+		pos, end token.Pos
+		want     bool
+	}{
+		{line(1), line(2), false},
+		{line(3), line(3), true},
+		{line(2), line(4), true},
+		{line(4), line(5), false},
+		{line(5), line(8), true},
+		{line(8), token.NoPos, false},
+		{line(7), token.NoPos, true},
+	}
+
+	for _, test := range tests {
+		if got := c.contains(fset, test.pos, test.end); got != test.want {
+			from, to := fset.Position(test.pos).Line, fset.Position(test.end).Line
+			t.Errorf("contains(%d, %d) = %t, want %t", from, to, got, test.want)
+		}
+	}
+
+	if !changes(nil).contains(fset, line(1), line(1)) {
+		t.Error("nil changes should contain every line")
+	}
+}
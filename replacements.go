@@ -12,12 +12,22 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
-//nolint:gochecknoglobals
-var imports = map[string]struct {
+// importRepl describes the replacement of a package import.
+type importRepl struct {
 	stdlib     string
 	minVersion string
 	pkgName    string
-}{
+}
+
+// callRepl describes the replacement of a function call.
+type callRepl struct {
+	stdlib     string
+	minVersion string
+	rewrite    RewriteFunc
+}
+
+//nolint:gochecknoglobals
+var imports = map[string]importRepl{
 	"golang.org/x/exp/maps":     {"maps", "go1.21", ""},
 	"golang.org/x/exp/rand":     {"math/rand/v2", "go1.22", "rand"},
 	"golang.org/x/exp/slices":   {"slices", "go1.21", ""},
@@ -27,11 +37,7 @@ var imports = map[string]struct {
 }
 
 //nolint:gochecknoglobals
-var calls = map[string]map[string]struct {
-	stdlib     string
-	minVersion string
-	rewrite    rewriteFunc
-}{
+var calls = map[string]map[string]callRepl{
 	"github.com/samber/lo": {
 		"Chunk":           {"slices.Chunk", "go1.23", nil},
 		"Drop":            {"", "go1", tmpl("{{index .Args 0}}[{{index .Args 1}}:]")},
@@ -56,7 +62,9 @@ var calls = map[string]map[string]struct {
 	},
 }
 
-type rewriteFunc func(*analysis.Pass, *ast.CallExpr) ([]analysis.TextEdit, bool)
+// RewriteFunc returns additional edits needed to replace a call expression. It returns false if the
+// call cannot be rewritten, in which case the finding is reported without a suggested fix.
+type RewriteFunc func(*analysis.Pass, *ast.CallExpr) ([]analysis.TextEdit, bool)
 
 // tmpl returns a rewrite function that replaces the entire call with the result of executing the template.
 func tmpl(templateStr string) func(pass *analysis.Pass, call *ast.CallExpr) ([]analysis.TextEdit, bool) {
@@ -117,7 +125,7 @@ func toVariadic(_ *analysis.Pass, call *ast.CallExpr) ([]analysis.TextEdit, bool
 
 // lessToCmp returns a rewrite function that converts a less function literal to a cmp function.
 // If reverse is true, the comparison is reversed.
-func lessToCmp(arg int, reverse bool) rewriteFunc {
+func lessToCmp(arg int, reverse bool) RewriteFunc {
 	return func(pass *analysis.Pass, call *ast.CallExpr) ([]analysis.TextEdit, bool) {
 		// Ensure the argument is a function literal.
 		funcLit, ok := call.Args[arg].(*ast.FuncLit)
@@ -182,7 +190,7 @@ func lessToCmp(arg int, reverse bool) rewriteFunc {
 	}
 }
 
-func keyToCmp(arg int) rewriteFunc { //nolint:funlen,gocognit
+func keyToCmp(arg int) RewriteFunc { //nolint:funlen,gocognit
 	return func(pass *analysis.Pass, call *ast.CallExpr) ([]analysis.TextEdit, bool) {
 		// Ensure the argument is a function literal.
 		funcLit, ok := call.Args[arg].(*ast.FuncLit)
@@ -0,0 +1,130 @@
+package stdlib
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/version"
+	"maps"
+	"os"
+	"strings"
+)
+
+// Rule describes the replacement of a package import or function call.
+type Rule struct {
+	// Package is the import path of the replaced package, e.g. "github.com/samber/lo".
+	Package string `json:"package"`
+
+	// Func is the name of the replaced function, e.g. "Contains". If empty, the rule replaces
+	// imports of the whole package.
+	Func string `json:"func,omitempty"`
+
+	// Replacement is the replacement function in "pkg.Func" form, e.g. "slices.Contains", or the
+	// import path of the replacement package if Func is empty. For functions, pkg must be both the
+	// package name and import path, so only standard library packages such as "slices" or "strings"
+	// are supported. It may be empty for functions which are replaced by a language feature using
+	// Rewrite.
+	Replacement string `json:"replacement,omitempty"`
+
+	// PkgName is the name of the replacement package if it differs from the last element of its
+	// import path, e.g. "rand" for "math/rand/v2". It is only used if Func is empty.
+	PkgName string `json:"pkgName,omitempty"`
+
+	// MinVersion is the minimum Go version supporting the replacement, e.g. "go1.21".
+	// Defaults to "go1".
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// Rewrite optionally returns additional edits needed to replace a function call.
+	Rewrite RewriteFunc `json:"-"`
+}
+
+// RulePack is a set of rules which can be added to the analyzer.
+type RulePack interface {
+	Rules() []Rule
+}
+
+// validate reports whether the rule is valid.
+func (r Rule) validate() error {
+	switch {
+	case r.Package == "":
+		return errors.New("missing package")
+	case r.Func == "" && r.Replacement == "":
+		return fmt.Errorf("missing replacement for package %q", r.Package)
+	case r.Func == "" && r.Rewrite != nil:
+		return fmt.Errorf("rewrite is not supported for package %q", r.Package)
+	case r.Func != "" && r.Replacement == "" && r.Rewrite == nil:
+		return fmt.Errorf("missing replacement or rewrite for %s.%s", r.Package, r.Func)
+	case r.Func != "" && r.Replacement != "" && !isQualifiedIdent(r.Replacement):
+		return fmt.Errorf("replacement %q for %s.%s not in 'pkg.Func' form", r.Replacement, r.Package, r.Func)
+	case r.Func != "" && r.PkgName != "":
+		return fmt.Errorf("package name is not supported for %s.%s", r.Package, r.Func)
+	case r.PkgName != "" && !token.IsIdentifier(r.PkgName):
+		return fmt.Errorf("invalid package name %q for package %q", r.PkgName, r.Package)
+	case r.MinVersion != "" && !version.IsValid(r.MinVersion):
+		return fmt.Errorf("invalid minimum version %q for package %q, must be in 'go1.N' form", r.MinVersion, r.Package)
+	}
+	return nil
+}
+
+// isQualifiedIdent reports whether s is an identifier qualified by a package name, e.g. "slices.Contains".
+func isQualifiedIdent(s string) bool {
+	pkg, name, ok := strings.Cut(s, ".")
+	return ok && token.IsIdentifier(pkg) && token.IsIdentifier(name)
+}
+
+// ruleSet contains the package and function replacements used by the analyzer.
+type ruleSet struct {
+	imports map[string]importRepl
+	calls   map[string]map[string]callRepl
+}
+
+// newRuleSet returns the built-in replacements extended with the rules. Later rules take precedence.
+func newRuleSet(rules []Rule) (ruleSet, error) {
+	rs := ruleSet{
+		imports: maps.Clone(imports),
+		calls:   make(map[string]map[string]callRepl, len(calls)),
+	}
+	for pkg, funcs := range calls {
+		rs.calls[pkg] = maps.Clone(funcs)
+	}
+
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return ruleSet{}, fmt.Errorf("invalid rule: %w", err)
+		}
+
+		minVersion := cmp.Or(r.MinVersion, "go1")
+
+		if r.Func == "" {
+			rs.imports[r.Package] = importRepl{r.Replacement, minVersion, r.PkgName}
+			continue
+		}
+		if rs.calls[r.Package] == nil {
+			rs.calls[r.Package] = make(map[string]callRepl)
+		}
+		rs.calls[r.Package][r.Func] = callRepl{r.Replacement, minVersion, r.Rewrite}
+	}
+
+	return rs, nil
+}
+
+// readRules reads a JSON array of rules from a file. Unknown fields are rejected so that typos
+// don't silently drop settings such as the minimum version.
+func readRules(name string) ([]Rule, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	var rules []Rule
+	if err := dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules from %s: %w", name, err)
+	}
+	return rules, nil
+}
@@ -9,52 +9,130 @@ import (
 	"go/types"
 	"go/version"
 	"path"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/analysis"
 )
 
+// Finding describes a use of a package or function which can be replaced by the standard library.
+// The analyzer's result is a []Finding which may be consumed by other analyzers that require it.
+// It contains every finding, including those not reported because they are outside the lines
+// changed relative to -diff-base.
+type Finding struct {
+	// Rule identifies the replaced package or function, e.g. "golang.org/x/exp/slices" or
+	// "github.com/samber/lo.Contains".
+	Rule string
+
+	// Pos and End are the positions of the import spec or function identifier.
+	Pos, End token.Pos
+
+	// Target is the replacement package or function, e.g. "slices" or "slices.Contains".
+	// It is empty if the function is replaced by a language feature.
+	Target string
+
+	// Fixable reports whether a suggested fix is available.
+	Fixable bool
+}
+
 // NewAnalyzer creates a new analyzer that detects uses of functions that can
-// be replaced by standard library functions.
-func NewAnalyzer() *analysis.Analyzer {
-	return &analysis.Analyzer{
-		Name: "stdlib",
-		Doc:  "Detects uses of functions that can be replaced by standard library functions and suggests fixes.",
+// be replaced by standard library functions. The rules of any rule packs are
+// added to the built-in replacements, taking precedence over them.
+func NewAnalyzer(packs ...RulePack) *analysis.Analyzer {
+	var (
+		diffBase  string
+		rulesFile string
+		setupOnce sync.Once
+		rules     ruleSet
+		diff      changes
+		setupErr  error
+	)
+
+	a := &analysis.Analyzer{
+		Name:       "stdlib",
+		Doc:        "Detects uses of functions that can be replaced by standard library functions and suggests fixes.",
+		ResultType: reflect.TypeFor[[]Finding](),
 		Run: func(pass *analysis.Pass) (any, error) {
+			setupOnce.Do(func() { rules, diff, setupErr = setup(packs, rulesFile, diffBase) })
+			if setupErr != nil {
+				return nil, setupErr
+			}
+
 			// references records, for each file and package, the number of candidate call expressions.
 			references := make(map[*ast.File]map[string]int)
 
+			var findings []Finding
+
 			// Process package import replacements first.
 			for _, file := range pass.Files {
-				processFileImports(pass, file)
+				findings = append(findings, processFileImports(pass, rules, file, diff)...)
 			}
 
 			// Replace call expressions in each file.
 			for _, file := range pass.Files {
-				processFileCalls(pass, file, references)
+				findings = append(findings, processFileCalls(pass, rules, file, diff, references)...)
 			}
 
 			// Remove unused imports.
-			processUnusedImports(pass, references)
+			processUnusedImports(pass, rules, references)
 
-			return nil, nil
+			return findings, nil
 		},
 	}
+
+	a.Flags.StringVar(&diffBase, "diff-base", "", "only report findings on lines changed relative to this git ref")
+	a.Flags.StringVar(&rulesFile, "rules", "", "JSON file containing additional rules")
+
+	return a
+}
+
+// setup returns the rules used by the analyzer and, if diffBase is set, the lines changed relative to it.
+func setup(packs []RulePack, rulesFile, diffBase string) (ruleSet, changes, error) {
+	var rules []Rule
+	for _, pack := range packs {
+		rules = append(rules, pack.Rules()...)
+	}
+	if rulesFile != "" {
+		fileRules, err := readRules(rulesFile)
+		if err != nil {
+			return ruleSet{}, nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	rs, err := newRuleSet(rules)
+	if err != nil {
+		return ruleSet{}, nil, err
+	}
+
+	// Only report findings on lines changed relative to the diff base, if one is set.
+	if diffBase == "" {
+		return rs, nil, nil
+	}
+	diff, err := gitChanges(diffBase)
+	if err != nil {
+		return ruleSet{}, nil, err
+	}
+	return rs, diff, nil
 }
 
 // processFileImports inspects a file for package imports that can be replaced.
-func processFileImports(pass *analysis.Pass, file *ast.File) {
+// Only imports on lines contained in diff are reported. It returns all findings.
+func processFileImports(pass *analysis.Pass, rules ruleSet, file *ast.File, diff changes) []Finding {
 	goVersion := cmp.Or(file.GoVersion, pass.Pkg.GoVersion(), "go1.9999")
 
+	var findings []Finding
+
 	for _, importSpec := range file.Imports {
 		pkgPath, err := strconv.Unquote(importSpec.Path.Value)
 		if err != nil {
 			continue
 		}
 
-		pkgRepl, ok := imports[pkgPath]
+		pkgRepl, ok := rules.imports[pkgPath]
 		if !ok || version.Compare(goVersion, pkgRepl.minVersion) < 0 {
 			continue
 		}
@@ -85,6 +163,18 @@ func processFileImports(pass *analysis.Pass, file *ast.File) {
 			}
 		}
 
+		findings = append(findings, Finding{
+			Rule:    pkgPath,
+			Pos:     importSpec.Pos(),
+			End:     importSpec.End(),
+			Target:  pkgRepl.stdlib,
+			Fixable: true,
+		})
+
+		if !diff.contains(pass.Fset, importSpec.Pos(), importSpec.End()) {
+			continue
+		}
+
 		pass.Report(analysis.Diagnostic{
 			Pos:     importSpec.Pos(),
 			End:     importSpec.End(),
@@ -94,13 +184,20 @@ func processFileImports(pass *analysis.Pass, file *ast.File) {
 			},
 		})
 	}
+
+	return findings
 }
 
 // processFileCalls inspects a file for call expressions that can be replaced.
 // It also records, per file and package, the number of references to each package.
-func processFileCalls(pass *analysis.Pass, file *ast.File, references map[*ast.File]map[string]int) {
+// Only calls on lines contained in diff are reported and recorded. It returns all findings.
+func processFileCalls(
+	pass *analysis.Pass, rules ruleSet, file *ast.File, diff changes, references map[*ast.File]map[string]int,
+) []Finding {
 	goVersion := cmp.Or(file.GoVersion, pass.Pkg.GoVersion(), "go1.9999")
 
+	var findings []Finding
+
 	ast.Inspect(file, func(n ast.Node) bool {
 		call, ok := n.(*ast.CallExpr)
 		if !ok {
@@ -116,26 +213,20 @@ func processFileCalls(pass *analysis.Pass, file *ast.File, references map[*ast.F
 		}
 		pkgPath := funcObj.Pkg().Path()
 		funcName := sel.Sel.Name
-		repl, ok := calls[pkgPath][funcName]
+		repl, ok := rules.calls[pkgPath][funcName]
 		if !ok {
 			return true
 		}
 		if version.Compare(goVersion, repl.minVersion) < 0 {
 			return true
 		}
-
 		pkg, ok := sel.X.(*ast.Ident)
 		if !ok {
 			return true
 		}
 
-		// Record references to this package using the local package name.
-		if references[file] == nil {
-			references[file] = make(map[string]int)
-		}
-		references[file][pkg.Name]++
-
 		fixes := addReplacementTextEdit(file, pkg, sel.Sel, repl.stdlib)
+		candidate := true
 
 		// If the replacement has a rewrite function, apply its edits.
 		if repl.rewrite != nil {
@@ -143,8 +234,8 @@ func processFileCalls(pass *analysis.Pass, file *ast.File, references map[*ast.F
 			if ok {
 				fixes = append(fixes, edits...)
 			} else {
-				fixes = nil                  // Don't suggest a fix if the rewrite failed.
-				references[file][pkg.Name]-- // Don't count this as a candidate.
+				fixes = nil       // Don't suggest a fix if the rewrite failed.
+				candidate = false // Don't count this as a candidate.
 			}
 		}
 
@@ -156,10 +247,33 @@ func processFileCalls(pass *analysis.Pass, file *ast.File, references map[*ast.F
 		if len(fixes) > 0 {
 			d.SuggestedFixes = []analysis.SuggestedFix{{Message: "Replace with stdlib function", TextEdits: fixes}}
 		}
+
+		findings = append(findings, Finding{
+			Rule:    pkgPath + "." + funcName,
+			Pos:     d.Pos,
+			End:     d.End,
+			Target:  repl.stdlib,
+			Fixable: len(d.SuggestedFixes) > 0,
+		})
+
+		if !diff.contains(pass.Fset, d.Pos, d.End) {
+			return true
+		}
+
 		pass.Report(d)
 
+		// Record references to this package using the local package name.
+		if candidate {
+			if references[file] == nil {
+				references[file] = make(map[string]int)
+			}
+			references[file][pkg.Name]++
+		}
+
 		return true
 	})
+
+	return findings
 }
 
 // addReplacementTextEdit returns a slice of TextEdits that replace the package and function identifiers.
@@ -211,7 +325,7 @@ func addReplacementTextEdit(file *ast.File, pkg, fn *ast.Ident, stdlib string) [
 
 // processUnusedImports checks whether a file’s import for a replaced package is no longer used,
 // and if so, suggests removing it.
-func processUnusedImports(pass *analysis.Pass, references map[*ast.File]map[string]int) {
+func processUnusedImports(pass *analysis.Pass, rules ruleSet, references map[*ast.File]map[string]int) {
 	// For each file, count usage of package names using the local alias.
 	for _, file := range pass.Files {
 		totalPkgUses := make(map[string]int)
@@ -230,7 +344,7 @@ func processUnusedImports(pass *analysis.Pass, references map[*ast.File]map[stri
 				continue
 			}
 			// Only consider packages that have a replacement configured.
-			if _, ok := calls[pkgPath]; !ok {
+			if _, ok := rules.calls[pkgPath]; !ok {
 				continue
 			}
 			// Determine the local alias: if a name is provided, use it; otherwise,
@@ -1,14 +1,19 @@
 package stdlib_test
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/abemedia/stdlib"
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestAnalyzer(t *testing.T) {
@@ -44,11 +49,218 @@ func TestAnalyzer(t *testing.T) {
 				t.Fatal(err, strings.TrimSpace(string(output)))
 			}
 
-			analysistest.RunWithSuggestedFixes(t, dir, stdlib.NewAnalyzer())
+			results := analysistest.RunWithSuggestedFixes(t, dir, stdlib.NewAnalyzer())
+			for _, result := range results {
+				checkFindings(t, result)
+			}
 		})
 	}
 }
 
+type rulePack []stdlib.Rule
+
+func (p rulePack) Rules() []stdlib.Rule { return p }
+
+func TestRulePack(t *testing.T) {
+	dir := filepath.Join(analysistest.TestData(), "rules")
+
+	t.Run("Pack", func(t *testing.T) {
+		pack := rulePack{
+			{Package: "test/oldctx", Replacement: "context"},
+			{Package: "test/old", Func: "Contains", Replacement: "slices.Contains", MinVersion: "go1.21"},
+			{Package: "test/old", Func: "Index", Replacement: "slices.Index", MinVersion: "go1.21"},
+		}
+		analysistest.RunWithSuggestedFixes(t, dir, stdlib.NewAnalyzer(pack))
+	})
+
+	t.Run("File", func(t *testing.T) {
+		a := stdlib.NewAnalyzer()
+		if err := a.Flags.Set("rules", filepath.Join(dir, "rules.json")); err != nil {
+			t.Fatal(err)
+		}
+		analysistest.RunWithSuggestedFixes(t, dir, a)
+	})
+}
+
+func TestRulePackInvalid(t *testing.T) {
+	pkgs := loadPackages(t, filepath.Join(analysistest.TestData(), "rules"), ".")
+
+	tests := []struct {
+		name string
+		rule stdlib.Rule
+	}{
+		{"MissingPackage", stdlib.Rule{Func: "Index", Replacement: "slices.Index"}},
+		{"MissingReplacement", stdlib.Rule{Package: "test/oldctx"}},
+		{"MissingFuncReplacement", stdlib.Rule{Package: "test/old", Func: "Index"}},
+		{"NotQualified", stdlib.Rule{Package: "test/old", Func: "Index", Replacement: "Index"}},
+		{"ImportPath", stdlib.Rule{Package: "test/old", Func: "Index", Replacement: "encoding/json.Marshal"}},
+		{"Domain", stdlib.Rule{Package: "test/old", Func: "Index", Replacement: "example.com/x.F"}},
+		{"PkgName", stdlib.Rule{Package: "test/oldctx", Replacement: "context", PkgName: "con-text"}},
+		{"MinVersion", stdlib.Rule{Package: "test/old", Func: "Index", Replacement: "slices.Index", MinVersion: "1.21"}},
+		{"FuncPkgName", stdlib.Rule{Package: "test/old", Func: "Index", Replacement: "slices.Index", PkgName: "slices"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := stdlib.NewAnalyzer(rulePack{test.rule})
+			graph, err := checker.Analyze([]*analysis.Analyzer{a}, pkgs, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, act := range graph.Roots {
+				if act.Err == nil || !strings.Contains(act.Err.Error(), "invalid rule") {
+					t.Errorf("got error %v, want invalid rule", act.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestRulesFileUnknownField(t *testing.T) {
+	pkgs := loadPackages(t, filepath.Join(analysistest.TestData(), "rules"), ".")
+
+	file := filepath.Join(t.TempDir(), "rules.json")
+	data := `[{"package": "test/old", "func": "Index", "replacement": "slices.Index", "min_version": "go1.21"}]`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := stdlib.NewAnalyzer()
+	if err := a.Flags.Set("rules", file); err != nil {
+		t.Fatal(err)
+	}
+	graph, err := checker.Analyze([]*analysis.Analyzer{a}, pkgs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, act := range graph.Roots {
+		if act.Err == nil || !strings.Contains(act.Err.Error(), `unknown field "min_version"`) {
+			t.Errorf("got error %v, want unknown field", act.Err)
+		}
+	}
+}
+
+func TestDiffBase(t *testing.T) {
+	tmp := t.TempDir()
+
+	// Use the go1.23 module with the files from testdata/diff.
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(analysistest.TestData(), "go1.23", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := copyFiles(filepath.Join(analysistest.TestData(), "diff"), tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Commit the original version of diff.go, leaving the modified version in the working tree
+	// and untracked.go untracked.
+	modified, err := os.ReadFile(filepath.Join(tmp, "diff.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(tmp, "diff.go.orig"), filepath.Join(tmp, "diff.go")); err != nil {
+		t.Fatal(err)
+	}
+	run := func(name string, args ...string) {
+		t.Helper()
+		cmd := exec.Command(name, args...)
+		cmd.Dir = tmp
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatal(err, strings.TrimSpace(string(output)))
+		}
+	}
+	run("go", "mod", "vendor")
+	run("git", "init", "--quiet")
+	run("git", "config", "diff.relative", "true") // Paths must still be relative to the repository root.
+	run("git", "add", "--all", "--", ".", ":!untracked.go")
+	run("git", "commit", "--quiet", "--message=init")
+	if err := os.WriteFile(filepath.Join(tmp, "diff.go"), modified, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// Run from a subdirectory of the repository.
+	if err := os.Chdir(filepath.Join(tmp, "vendor")); err != nil {
+		t.Fatal(err)
+	}
+
+	a := stdlib.NewAnalyzer()
+	if err := a.Flags.Set("diff-base", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	results := analysistest.RunWithSuggestedFixes(t, tmp, a)
+
+	// The result contains all findings, including those on unchanged lines.
+	for _, result := range results {
+		findings, ok := result.Result.([]stdlib.Finding)
+		if !ok {
+			t.Fatalf("unexpected result type %T", result.Result)
+		}
+		var rules []string
+		for _, f := range findings {
+			rules = append(rules, f.Rule)
+		}
+		slices.Sort(rules)
+		want := []string{
+			"github.com/samber/lo.Contains",
+			"github.com/samber/lo.IndexOf",
+			"github.com/samber/lo.Max",
+			"golang.org/x/exp/slices",
+		}
+		if !slices.Equal(rules, want) {
+			t.Errorf("got findings %q, want %q", rules, want)
+		}
+	}
+}
+
+// checkFindings checks that the analyzer's result contains a finding for each reported diagnostic,
+// except for those suggesting the removal of unused imports.
+func checkFindings(t *testing.T, result *analysistest.Result) {
+	t.Helper()
+
+	findings, ok := result.Result.([]stdlib.Finding)
+	if !ok {
+		t.Fatalf("unexpected result type %T", result.Result)
+	}
+
+	var diagnostics []analysis.Diagnostic
+	for _, d := range result.Diagnostics {
+		if !strings.HasSuffix(d.Message, "import is no longer necessary") {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	if len(findings) != len(diagnostics) {
+		t.Fatalf("got %d findings, want %d", len(findings), len(diagnostics))
+	}
+
+	for i, f := range findings {
+		d := diagnostics[i]
+		pos := result.Pass.Fset.Position(f.Pos)
+		if f.Pos != d.Pos || f.End != d.End {
+			t.Errorf("%s: finding %s does not match diagnostic %q", pos, f.Rule, d.Message)
+		}
+		if f.Rule == "" {
+			t.Errorf("%s: finding has no rule", pos)
+		}
+		if f.Fixable != (len(d.SuggestedFixes) > 0) {
+			t.Errorf("%s: finding %s has fixable %t, want %t", pos, f.Rule, f.Fixable, !f.Fixable)
+		}
+	}
+}
+
 func copyFiles(source, destination string) error {
 	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
 		rel := strings.Replace(path, source, "", 1)
@@ -65,3 +277,173 @@ func copyFiles(source, destination string) error {
 		return os.WriteFile(filepath.Join(destination, rel), data, info.Mode().Perm())
 	})
 }
+
+// benchmarkCase describes a package analyzed by BenchmarkAnalyzer and TestAnalyzerRegression.
+type benchmarkCase struct {
+	name        string
+	dir         string
+	pattern     string
+	diagnostics int     // expected number of diagnostics
+	maxAllocs   float64 // upper bound on allocations per run, with headroom for noise
+}
+
+// benchmarkCases returns the packages to benchmark. Update diagnostics and maxAllocs when a change
+// intentionally affects them. The allocations depend on the Go and x/tools versions, so maxAllocs
+// is only checked when $STDLIB_CHECK_ALLOCS is set.
+func benchmarkCases() []benchmarkCase {
+	return []benchmarkCase{
+		{name: "go1.18", dir: "go1.18", pattern: ".", diagnostics: 20, maxAllocs: 1_000},
+		{name: "go1.23", dir: "go1.23", pattern: ".", diagnostics: 44, maxAllocs: 1_900},
+		{name: "synthetic", dir: "go1.23", pattern: "./synthetic", diagnostics: 5050, maxAllocs: 275_000},
+	}
+}
+
+// setupBenchmarkData copies and vendors the test data and generates the synthetic package.
+// It returns the directory containing the test data.
+func setupBenchmarkData(tb testing.TB) string {
+	tb.Helper()
+
+	tmp := tb.TempDir()
+
+	if err := copyFiles(analysistest.TestData(), tmp); err != nil {
+		tb.Fatal(err)
+	}
+
+	for _, dir := range []string{"go1.18", "go1.23"} {
+		cmd := exec.Command("go", "mod", "vendor")
+		cmd.Dir = filepath.Join(tmp, dir)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			tb.Fatal(err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	// Generate a package with many files and calls to measure how the analyzer scales.
+	if err := writeSyntheticPackage(filepath.Join(tmp, "go1.23", "synthetic"), 50, 20); err != nil {
+		tb.Fatal(err)
+	}
+
+	return tmp
+}
+
+func BenchmarkAnalyzer(b *testing.B) {
+	tmp := setupBenchmarkData(b)
+
+	for _, test := range benchmarkCases() {
+		pkgs := loadPackages(b, filepath.Join(tmp, test.dir), test.pattern)
+		b.Run(test.name, func(b *testing.B) { benchmarkAnalyzer(b, pkgs) })
+	}
+}
+
+// TestAnalyzerRegression fails if the number of diagnostics of the benchmarked packages changes.
+// If $STDLIB_CHECK_ALLOCS is set, it also fails if their allocations exceed the expected maximum.
+func TestAnalyzerRegression(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+
+	tmp := setupBenchmarkData(t)
+
+	for _, test := range benchmarkCases() {
+		t.Run(test.name, func(t *testing.T) {
+			pkgs := loadPackages(t, filepath.Join(tmp, test.dir), test.pattern)
+
+			if got := analyze(t, pkgs); got != test.diagnostics {
+				t.Errorf("got %d diagnostics, want %d", got, test.diagnostics)
+			}
+
+			if os.Getenv("STDLIB_CHECK_ALLOCS") == "" {
+				return
+			}
+			allocs := testing.AllocsPerRun(5, func() { analyze(t, pkgs) })
+			if allocs > test.maxAllocs {
+				t.Errorf("got %.0f allocations per run, want at most %.0f", allocs, test.maxAllocs)
+			}
+			t.Logf("%.0f allocations per run", allocs)
+		})
+	}
+}
+
+// writeSyntheticPackage writes a package containing the given number of files, each containing
+// the given number of functions calling replaceable functions.
+func writeSyntheticPackage(dir string, files, funcs int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for i := range files {
+		var buf strings.Builder
+		buf.WriteString("package synthetic\n\nimport \"github.com/samber/lo\"\n")
+		for j := range funcs {
+			fmt.Fprintf(&buf, `
+func f%d_%d(a []int, m map[string]int) {
+	_ = lo.Contains(a, 1)
+	_ = lo.Drop(a, 1)
+	_ = lo.MinBy(a, func(x, y int) bool { return x < y })
+	_ = lo.IsSortedByKey(a, func(x int) int { return -x })
+	_ = lo.Keys(m)
+}
+`, i, j)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.go", i)), []byte(buf.String()), 0o600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPackages loads the packages matching the patterns, skipping any packages containing errors.
+func loadPackages(tb testing.TB, dir string, patterns ...string) []*packages.Package {
+	tb.Helper()
+
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	valid := slices.DeleteFunc(pkgs, func(pkg *packages.Package) bool { return len(pkg.Errors) > 0 })
+	if skipped := len(pkgs) - len(valid); skipped > 0 {
+		tb.Logf("skipped %d packages containing errors", skipped)
+	}
+	if len(valid) == 0 {
+		tb.Fatal("no packages loaded")
+	}
+
+	return valid
+}
+
+// benchmarkAnalyzer benchmarks running the analyzer on the packages and reports the number of
+// diagnostics.
+func benchmarkAnalyzer(b *testing.B, pkgs []*packages.Package) {
+	b.Helper()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var diagnostics int
+	for range b.N {
+		diagnostics = analyze(b, pkgs)
+	}
+
+	b.ReportMetric(float64(diagnostics), "diagnostics")
+}
+
+// analyze runs the analyzer sequentially on the packages and returns the number of diagnostics.
+func analyze(tb testing.TB, pkgs []*packages.Package) int {
+	tb.Helper()
+
+	opts := &checker.Options{Sequential: true}
+	graph, err := checker.Analyze([]*analysis.Analyzer{stdlib.NewAnalyzer()}, pkgs, opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var diagnostics int
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			tb.Fatalf("%s: %v", act.Package, act.Err)
+		}
+		diagnostics += len(act.Diagnostics)
+	}
+	return diagnostics
+}
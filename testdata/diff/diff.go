@@ -0,0 +1,8 @@
+package test
+
+import "github.com/samber/lo"
+
+func _(a []int) {
+	lo.Contains(a, 1)
+	lo.IndexOf(a, 2) // want `lo.IndexOf can be replaced with slices.Index`
+}
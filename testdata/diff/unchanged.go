@@ -0,0 +1,7 @@
+package test
+
+import "golang.org/x/exp/slices"
+
+func _(a []int) {
+	slices.Clone(a)
+}
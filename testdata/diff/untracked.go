@@ -0,0 +1,9 @@
+package test
+
+import (
+	"github.com/samber/lo" // want "The github.com/samber/lo package import is no longer necessary"
+)
+
+func _(a []int) {
+	lo.Max(a) // want `lo.Max can be replaced with slices.Max`
+}
@@ -0,0 +1,9 @@
+package old
+
+func Contains[T comparable](s []T, v T) bool {
+	return false
+}
+
+func Index[T comparable](s []T, v T) int {
+	return -1
+}
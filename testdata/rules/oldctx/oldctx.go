@@ -0,0 +1,7 @@
+package oldctx
+
+import "context"
+
+func Background() context.Context {
+	return context.Background()
+}
@@ -0,0 +1,12 @@
+package test
+
+import (
+	"test/old"    // want "The test/old package import is no longer necessary"
+	"test/oldctx" // want "Package \"test/oldctx\" can be replaced with \"context\""
+)
+
+func _(a []string) {
+	old.Contains(a, "a") // want `old.Contains can be replaced with slices.Contains`
+	old.Index(a, "a")    // want `old.Index can be replaced with slices.Index`
+	oldctx.Background()
+}